@@ -5,6 +5,7 @@ package builder
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"github.com/gen0cide/laforge/builder/tfgcp"
 	"github.com/gen0cide/laforge/core/cli"
@@ -125,6 +126,33 @@ func (b *BuildEngine) Do() error {
 			os.Exit(1)
 		}
 	}
+	psids := make([]string, 0, len(b.Base.ProvisioningSteps))
+	for id := range b.Base.ProvisioningSteps {
+		psids = append(psids, id)
+	}
+	sort.Strings(psids)
+	perrs := core.MultiError{}
+	for _, id := range psids {
+		ps := b.Base.ProvisioningSteps[id]
+		if ps.Provisioner == nil {
+			continue
+		}
+		err = ps.Provisioner.Validate()
+		if err != nil {
+			perrs = append(perrs, errors.Wrapf(err, "provisioning_step=%s", ps.Path()))
+		}
+	}
+	if len(perrs) > 0 {
+		return buildutil.Throw(perrs, "provisioners failed validation", &buildutil.V{"failures": len(perrs)})
+	}
+	cli.Logger.Infof("Provisioner validations passed")
+	if b.Base.CurrentCompetition != nil && b.Base.CurrentCompetition.Remote != nil {
+		err = b.Base.CurrentCompetition.Remote.Validate()
+		if err != nil {
+			return buildutil.Throw(err, "remote state config failed validation", &buildutil.V{"competition": b.Base.CurrentCompetition.ID})
+		}
+		cli.Logger.Infof("Remote state validation passed")
+	}
 	err = b.Builder.CheckRequirements()
 	if err != nil {
 		return buildutil.Throw(err, "failed checking requirements", nil)
@@ -3,7 +3,6 @@ package templates
 import (
 	"errors"
 	"fmt"
-	"runtime"
 
 	"github.com/gen0cide/laforge/builder/buildutil"
 	"github.com/gen0cide/laforge/core"
@@ -100,11 +99,7 @@ func (c *Context) Localize() {
 	if c.Local != nil {
 		return
 	}
-	locale := &core.Local{
-		OS:   runtime.GOOS,
-		Arch: runtime.GOARCH,
-	}
-	c.Local = locale
+	c.Local = core.NewLocal()
 	return
 }
 
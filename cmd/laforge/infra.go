@@ -102,7 +102,7 @@ func performtaint(c *cli.Context) error {
 			continue
 		}
 
-		obj.Checksum = 666
+		obj.Tainted = true
 	}
 
 	plan, err := state.CalculateDelta()
@@ -1,5 +1,24 @@
 package core
 
+import (
+	"fmt"
+
+	"github.com/cespare/xxhash"
+	"github.com/pkg/errors"
+)
+
+var (
+	// AMIProviders is the set of infrastructure providers an AMI can be defined for
+	AMIProviders = []string{
+		"aws",
+		"azure",
+		"gcp",
+		"openstack",
+		"vsphere",
+		"vagrant",
+	}
+)
+
 // AMI represents a configurable object for defining custom AMIs in cloud infrastructure
 //easyjson:json
 type AMI struct {
@@ -10,5 +29,89 @@ type AMI struct {
 	Username    string            `hcl:"username,attr" json:"username,omitempty"`
 	Vars        map[string]string `hcl:"vars,optional" json:"vars,omitempty"`
 	Tags        map[string]string `hcl:"tags,optional" json:"tags,omitempty"`
+	RegionMap   map[string]string `hcl:"region_map,optional" json:"region_map,omitempty"`
 	Maintainer  *User             `hcl:"maintainer,block" json:"maintainer,omitempty"`
 }
+
+// Hash implements the Hasher interface
+func (a *AMI) Hash() uint64 {
+	return xxhash.Sum64String(
+		fmt.Sprintf(
+			"id=%v name=%v provider=%v username=%v vars=%v tags=%v region_map=%v",
+			a.ID,
+			a.Name,
+			a.Provider,
+			a.Username,
+			HashStringMap(a.Vars),
+			HashStringMap(a.Tags),
+			HashStringMap(a.RegionMap),
+		),
+	)
+}
+
+// Validate checks the AMI configuration for required fields and a known provider, reporting every problem found
+func (a *AMI) Validate() error {
+	errs := MultiError{}
+	if a.ID == "" {
+		errs = append(errs, errors.New("ami id must be specified"))
+	}
+	if a.Name == "" {
+		errs = append(errs, errors.Errorf("ami %s: name must be specified", a.ID))
+	}
+	if a.Username == "" {
+		errs = append(errs, errors.Errorf("ami %s: username must be specified", a.ID))
+	}
+
+	knownProvider := false
+	for _, x := range AMIProviders {
+		if a.Provider == x {
+			knownProvider = true
+			break
+		}
+	}
+	if !knownProvider {
+		errs = append(errs, errors.Errorf("ami %s: unknown provider %q (expected one of %v)", a.ID, a.Provider, AMIProviders))
+	}
+
+	for k := range a.Vars {
+		if k == "" {
+			errs = append(errs, errors.Errorf("ami %s: vars contains an empty key", a.ID))
+		}
+	}
+	for _, err := range ValidateTagKeys(a.Tags) {
+		errs = append(errs, errors.Wrapf(err, "ami %s", a.ID))
+	}
+	for k, v := range a.RegionMap {
+		if k == "" {
+			errs = append(errs, errors.Errorf("ami %s: region_map contains an empty region", a.ID))
+			continue
+		}
+		if v == "" {
+			errs = append(errs, errors.Errorf("ami %s: region_map has an empty image id for region %s", a.ID, k))
+		}
+	}
+
+	return errs.ErrOrNil()
+}
+
+// IDForRegion returns the image ID mapped to region, falling back to the AMI's ID when the region is not mapped
+func (a *AMI) IDForRegion(region string) (string, error) {
+	if id, ok := a.RegionMap[region]; ok && id != "" {
+		return id, nil
+	}
+	if a.ID == "" {
+		return "", errors.Errorf("ami has no image id for region %s", region)
+	}
+	return a.ID, nil
+}
+
+// NormalizeTags trims whitespace from the AMI's tag keys, optionally lowercasing them. If any keys collide once
+// normalized, an error is returned and the tags are left unchanged.
+func (a *AMI) NormalizeTags(lowercase bool) error {
+	tags, err := NormalizeTagKeys(a.Tags, lowercase)
+	if err != nil {
+		return errors.Wrapf(err, "ami %s", a.ID)
+	}
+	a.Tags = tags
+	return nil
+}
@@ -0,0 +1,40 @@
+package core
+
+import "testing"
+
+func TestAMIHash(t *testing.T) {
+	ami := func() *AMI {
+		return &AMI{
+			ID:        "ubuntu",
+			Name:      "ubuntu-18.04",
+			Provider:  "aws",
+			Username:  "ubuntu",
+			Vars:      map[string]string{"a": "1", "b": "2"},
+			Tags:      map[string]string{"team": "blue"},
+			RegionMap: map[string]string{"us-east-1": "ami-1234"},
+		}
+	}
+
+	if ami().Hash() != ami().Hash() {
+		t.Fatal("expected identical AMIs to hash equal")
+	}
+
+	mutations := map[string]func(a *AMI){
+		"id":         func(a *AMI) { a.ID = "centos" },
+		"name":       func(a *AMI) { a.Name = "ubuntu-20.04" },
+		"provider":   func(a *AMI) { a.Provider = "gcp" },
+		"username":   func(a *AMI) { a.Username = "root" },
+		"vars":       func(a *AMI) { a.Vars["a"] = "3" },
+		"tags":       func(a *AMI) { a.Tags["team"] = "red" },
+		"region_map": func(a *AMI) { a.RegionMap["us-west-2"] = "ami-5678" },
+	}
+	for field, mutate := range mutations {
+		t.Run(field, func(t *testing.T) {
+			a := ami()
+			mutate(a)
+			if a.Hash() == ami().Hash() {
+				t.Fatalf("expected changing %s to change the hash", field)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package core
 import (
 	"encoding/binary"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/cespare/xxhash"
 )
@@ -20,6 +22,30 @@ func (c ChecksumList) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
 // Less implements the sort interface
 func (c ChecksumList) Less(i, j int) bool { return c[i] < c[j] }
 
+// HashStringMap computes a checksum of a string map which is independent of the map's iteration order.
+// Keys and values are length prefixed so that no two distinct maps share an encoding.
+func HashStringMap(m map[string]string) uint64 {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		writeLengthPrefixed(&b, k)
+		writeLengthPrefixed(&b, m[k])
+	}
+	return xxhash.Sum64String(b.String())
+}
+
+// writeLengthPrefixed writes s to b preceded by its length
+func writeLengthPrefixed(b *strings.Builder, s string) {
+	b.WriteString(strconv.Itoa(len(s)))
+	b.WriteByte(':')
+	b.WriteString(s)
+}
+
 // Hash implements the hasher interface
 func (c ChecksumList) Hash() uint64 {
 	sort.Sort(c)
@@ -0,0 +1,28 @@
+package core
+
+import "testing"
+
+func TestHashStringMap(t *testing.T) {
+	if HashStringMap(map[string]string{"a": "1", "b": "2"}) != HashStringMap(map[string]string{"b": "2", "a": "1"}) {
+		t.Fatal("expected equal maps to hash equal")
+	}
+
+	distinct := []map[string]string{
+		nil,
+		{"": ""},
+		{"a=b": ""},
+		{"a": "b="},
+		{"a": "b", "c": ""},
+		{"a": "b\x00c="},
+		{"a": "1"},
+		{"a": "2"},
+	}
+	seen := map[uint64]int{}
+	for i, m := range distinct {
+		h := HashStringMap(m)
+		if j, ok := seen[h]; ok {
+			t.Fatalf("%v and %v hashed equal", distinct[j], m)
+		}
+		seen[h] = i
+	}
+}
@@ -123,6 +123,18 @@ func (c *Command) Kind() string {
 	return ObjectTypeCommand.String()
 }
 
+// Validate implements the Provisioner interface
+func (c *Command) Validate() error {
+	errs := MultiError{}
+	if c.Program == "" {
+		errs = append(errs, errors.Errorf("command %s: program must be specified", c.ID))
+	}
+	if err := validateVarKeys(c.ID, c.Vars); err != nil {
+		errs = append(errs, err)
+	}
+	return errs.ErrOrNil()
+}
+
 // CommandString is a template helper function to embed commands into the output
 func (c *Command) CommandString() string {
 	cmd := []string{c.Program}
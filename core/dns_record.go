@@ -95,6 +95,21 @@ func (r *DNSRecord) Kind() string {
 	return ObjectTypeDNSRecord.String()
 }
 
+// Validate implements the Provisioner interface
+func (r *DNSRecord) Validate() error {
+	errs := MultiError{}
+	if r.Name == "" {
+		errs = append(errs, errors.Errorf("dns record %s: name must be specified", r.ID))
+	}
+	if r.Type == "" {
+		errs = append(errs, errors.Errorf("dns record %s: type must be specified", r.ID))
+	}
+	if err := validateVarKeys(r.ID, r.Vars); err != nil {
+		errs = append(errs, err)
+	}
+	return errs.ErrOrNil()
+}
+
 // Fullpath implements the Pather interface
 func (r *DNSRecord) Fullpath() string {
 	return r.LaforgeID()
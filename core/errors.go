@@ -0,0 +1,26 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MultiError collects several errors that occurred during a single operation so they can be reported together
+type MultiError []error
+
+// Error implements the error interface
+func (m MultiError) Error() string {
+	lines := make([]string, 0, len(m))
+	for _, x := range m {
+		lines = append(lines, fmt.Sprintf("  * %v", x))
+	}
+	return fmt.Sprintf("%d error(s) occurred:\n%s", len(m), strings.Join(lines, "\n"))
+}
+
+// ErrOrNil returns nil when no errors have been collected, allowing a MultiError to be returned directly as an error
+func (m MultiError) ErrOrNil() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
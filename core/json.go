@@ -1841,6 +1841,31 @@ func easyjsonA5722a85DecodeGithubComGen0cideLaforgeCore9(in *jlexer.Lexer, out *
 			}
 		case "external_id":
 			out.ExternalID = string(in.String())
+		case "external_ids":
+			if in.IsNull() {
+				in.Skip()
+				out.ExternalIDs = nil
+			} else {
+				in.Delim('[')
+				if out.ExternalIDs == nil {
+					if !in.IsDelim(']') {
+						out.ExternalIDs = make([]string, 0, 4)
+					} else {
+						out.ExternalIDs = []string{}
+					}
+				} else {
+					out.ExternalIDs = (out.ExternalIDs)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v188 string
+					v188 = string(in.String())
+					out.ExternalIDs = append(out.ExternalIDs, v188)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "schema_version":
+			out.SchemaVersion = int(in.Int())
 		case "vars":
 			if in.IsNull() {
 				in.Skip()
@@ -1905,6 +1930,27 @@ func easyjsonA5722a85EncodeGithubComGen0cideLaforgeCore9(out *jwriter.Writer, in
 		out.RawString(prefix)
 		out.String(string(in.ExternalID))
 	}
+	{
+		const prefix string = ",\"external_ids\":"
+		out.RawString(prefix)
+		if in.ExternalIDs == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v189, v190 := range in.ExternalIDs {
+				if v189 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v190))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"schema_version\":"
+		out.RawString(prefix)
+		out.Int(int(in.SchemaVersion))
+	}
 	{
 		const prefix string = ",\"vars\":"
 		out.RawString(prefix)
@@ -8886,6 +8932,26 @@ func easyjsonA5722a85DecodeGithubComGen0cideLaforgeCore44(in *jlexer.Lexer, out
 				}
 				in.Delim('}')
 			}
+		case "region_map":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.RegionMap = make(map[string]string)
+				} else {
+					out.RegionMap = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v191 string
+					v191 = string(in.String())
+					(out.RegionMap)[key] = v191
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
 		case "maintainer":
 			if in.IsNull() {
 				in.Skip()
@@ -9004,6 +9070,30 @@ func easyjsonA5722a85EncodeGithubComGen0cideLaforgeCore44(out *jwriter.Writer, i
 			out.RawByte('}')
 		}
 	}
+	if len(in.RegionMap) != 0 {
+		const prefix string = ",\"region_map\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('{')
+			v192First := true
+			for v192Name, v192Value := range in.RegionMap {
+				if v192First {
+					v192First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v192Name))
+				out.RawByte(':')
+				out.String(string(v192Value))
+			}
+			out.RawByte('}')
+		}
+	}
 	if in.Maintainer != nil {
 		const prefix string = ",\"maintainer\":"
 		if first {
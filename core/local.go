@@ -1,9 +1,30 @@
 package core
 
+import (
+	"os"
+	"runtime"
+)
+
 // Local is used to represent information about the current runtime to the user
 type Local struct {
-	OS   string
-	Arch string
+	OS       string
+	Arch     string
+	Hostname string
+	NumCPU   int
+}
+
+// NewLocal returns a Local populated from the current runtime
+func NewLocal() *Local {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	return &Local{
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Hostname: hostname,
+		NumCPU:   runtime.NumCPU(),
+	}
 }
 
 // IsWindows is a template helper function
@@ -20,3 +41,28 @@ func (l *Local) IsMacOS() bool {
 func (l *Local) IsLinux() bool {
 	return l.OS == "linux"
 }
+
+// IsUnix is a template helper function
+func (l *Local) IsUnix() bool {
+	switch l.OS {
+	case "linux", "darwin", "freebsd":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsAMD64 is a template helper function
+func (l *Local) IsAMD64() bool {
+	return l.Arch == "amd64"
+}
+
+// IsARM64 is a template helper function
+func (l *Local) IsARM64() bool {
+	return l.Arch == "arm64"
+}
+
+// Is386 is a template helper function
+func (l *Local) Is386() bool {
+	return l.Arch == "386"
+}
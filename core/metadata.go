@@ -123,6 +123,45 @@ const (
 	LFTypeUnknown LFType = "unknown"
 )
 
+var (
+	// LFTypes is the full set of object types Laforge resolves from an ID
+	LFTypes = []LFType{
+		LFTypeCompetition,
+		LFTypeNetwork,
+		LFTypeHost,
+		LFTypeRemoteFile,
+		LFTypeCommand,
+		LFTypeDNSRecord,
+		LFTypeScript,
+		LFTypeEnvironment,
+		LFTypeBuild,
+		LFTypeTeam,
+		LFTypeProvisionedNetwork,
+		LFTypeProvisionedHost,
+		LFTypeConnection,
+		LFTypeProvisioningStep,
+	}
+
+	// ErrInvalidLFType is thrown when a string does not match any known LFType
+	ErrInvalidLFType = errors.New("invalid laforge object type")
+)
+
+// String implements the stringer interface
+func (t LFType) String() string {
+	return string(t)
+}
+
+// ParseLFType attempts to resolve a string into one of the known LFType values. LFTypeUnknown is only ever returned
+// alongside ErrInvalidLFType.
+func ParseLFType(s string) (LFType, error) {
+	for _, x := range LFTypes {
+		if string(x) == s {
+			return x, nil
+		}
+	}
+	return LFTypeUnknown, errors.Wrapf(ErrInvalidLFType, "type=%s", s)
+}
+
 // TypeByPath is a helper function specifically for metadata to call TypeByPath easily
 func (m *Metadata) TypeByPath() LFType {
 	return TypeByPath(m.ID)
@@ -416,11 +455,12 @@ func (m *Metadata) CalculateChecksum() {
 // ToRevision generates a revision object for m
 func (m *Metadata) ToRevision() *Revision {
 	return &Revision{
-		ID:        m.ID,
-		Type:      TypeByPath(m.ID),
-		Status:    RevStatusPlanned,
-		Checksum:  m.Checksum,
-		Timestamp: time.Now(),
+		ID:            m.ID,
+		Type:          TypeByPath(m.ID),
+		Status:        RevStatusPlanned,
+		Checksum:      m.Checksum,
+		Timestamp:     time.Now(),
+		SchemaVersion: RevisionSchemaVersion,
 	}
 }
 
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseLFType(t *testing.T) {
+	for _, x := range LFTypes {
+		got, err := ParseLFType(x.String())
+		if err != nil {
+			t.Fatalf("ParseLFType(%q) returned an error: %v", x, err)
+		}
+		if got != x {
+			t.Fatalf("ParseLFType(%q) returned %q", x, got)
+		}
+	}
+
+	for _, s := range []string{"unknown", "", "bogus"} {
+		got, err := ParseLFType(s)
+		if errors.Cause(err) != ErrInvalidLFType {
+			t.Fatalf("ParseLFType(%q) returned err=%v, expected ErrInvalidLFType", s, err)
+		}
+		if got != LFTypeUnknown {
+			t.Fatalf("ParseLFType(%q) returned %q, expected %q", s, got, LFTypeUnknown)
+		}
+	}
+}
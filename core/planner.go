@@ -169,8 +169,16 @@ func (p *Plan) WriteRevisionFile(d Doer, status RevStatus) error {
 	filename := fmt.Sprintf(".%s.pstep.lfrevision", filepath.Base(d.GetTargetID()))
 	pathToRevFile := filepath.Join(p.Base.BaseDir, filepath.Dir(d.GetTargetID()), filename)
 	rev := d.GetMetadata().ToRevision()
-	rev.Touch()
-	rev.Status = status
+	switch status {
+	case RevStatusActive:
+		rev.Touch()
+	case RevStatusFailed:
+		rev.MarkFailed()
+	case RevStatusStale:
+		rev.Taint()
+	default:
+		rev.setStatus(status)
+	}
 	err := ioutil.WriteFile(pathToRevFile, []byte(rev.ToJSONString()), 0644)
 	if err != nil {
 		return err
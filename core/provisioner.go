@@ -1,6 +1,9 @@
 package core
 
-import "github.com/gen0cide/laforge/core/graph"
+import (
+	"github.com/gen0cide/laforge/core/graph"
+	"github.com/pkg/errors"
+)
 
 // Provisioner is a meta interface to provide provisioning steps to the Builder
 type Provisioner interface {
@@ -12,4 +15,25 @@ type Provisioner interface {
 
 	// Kind denotes the type of Provisioner this is
 	Kind() string
+
+	// Validate performs config-time sanity checks on the provisioner before a build is attempted
+	Validate() error
+}
+
+// BaseProvisioner can be embedded by Provisioner implementations that have no config-time validation to perform
+type BaseProvisioner struct{}
+
+// Validate implements the Provisioner interface
+func (b BaseProvisioner) Validate() error {
+	return nil
+}
+
+// validateVarKeys ensures none of the provided vars have an empty key
+func validateVarKeys(id string, vars map[string]string) error {
+	for k := range vars {
+		if k == "" {
+			return errors.Errorf("%s: vars contains an empty key", id)
+		}
+	}
+	return nil
 }
@@ -133,6 +133,26 @@ func (r *RemoteFile) Kind() string {
 	return ObjectTypeRemoteFile.String()
 }
 
+// Validate implements the Provisioner interface
+func (r *RemoteFile) Validate() error {
+	errs := MultiError{}
+	if r.Source == "" {
+		errs = append(errs, errors.Errorf("remote file %s: source must be specified", r.ID))
+	}
+	if r.Destination == "" {
+		errs = append(errs, errors.Errorf("remote file %s: destination must be specified", r.ID))
+	}
+	if r.AbsPath != "" {
+		if _, err := os.Stat(r.AbsPath); err != nil {
+			errs = append(errs, errors.Wrapf(err, "remote file %s: source could not be located", r.ID))
+		}
+	}
+	if err := validateVarKeys(r.ID, r.Vars); err != nil {
+		errs = append(errs, err)
+	}
+	return errs.ErrOrNil()
+}
+
 // Swap implements the Mergeable interface
 func (r *RemoteFile) Swap(m Mergeable) error {
 	rawVal, ok := m.(*RemoteFile)
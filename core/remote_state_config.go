@@ -2,8 +2,26 @@ package core
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/cespare/xxhash"
+	"github.com/pkg/errors"
+)
+
+var (
+	// RemoteBackends maps each supported remote state backend type to the config keys it requires
+	RemoteBackends = map[string][]string{
+		"disabled": {},
+		"local":    {},
+		"s3":       {"bucket", "key"},
+		"gcs":      {"bucket"},
+		"consul":   {"path"},
+		"azurerm":  {"storage_account_name", "container_name", "key"},
+		"etcdv3":   {"endpoints"},
+		"http":     {"address"},
+	}
 )
 
 // Remote defines a configuration object that keeps terraform and remote files synchronized
@@ -16,12 +34,94 @@ type Remote struct {
 
 // Hash implements the Hasher interface
 func (r *Remote) Hash() uint64 {
+	config, _ := r.resolveConfig(false)
 	return xxhash.Sum64String(
 		fmt.Sprintf(
 			"id=%v type=%v config=%v",
 			r.ID,
 			r.Type,
-			r.Config,
+			HashStringMap(config),
 		),
 	)
 }
+
+// DeepCopy returns a copy of the remote which shares no maps with the original
+func (r *Remote) DeepCopy() *Remote {
+	cp := *r
+	if r.Config != nil {
+		cp.Config = make(map[string]string, len(r.Config))
+		for k, v := range r.Config {
+			cp.Config[k] = v
+		}
+	}
+	return &cp
+}
+
+// Validate ensures the remote uses a supported backend type and that every config key that backend requires is set
+// once environment variable references have been resolved
+func (r *Remote) Validate() error {
+	required, ok := RemoteBackends[r.Type]
+	if !ok {
+		types := make([]string, 0, len(RemoteBackends))
+		for x := range RemoteBackends {
+			types = append(types, x)
+		}
+		sort.Strings(types)
+		return errors.Errorf("remote %s: unknown backend type %q (expected one of %s)", r.ID, r.Type, strings.Join(types, ", "))
+	}
+
+	config, _ := r.resolveConfig(false)
+	missing := []string{}
+	for _, k := range required {
+		if config[k] == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("remote %s: %s backend is missing required config keys: %s", r.ID, r.Type, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// ResolveEnv expands environment variable references (${VAR} or $VAR) in each Config value in place.
+// When strict is set, references to unset environment variables are returned as an error and Config is left unchanged.
+func (r *Remote) ResolveEnv(strict bool) error {
+	config, err := r.resolveConfig(strict)
+	if err != nil {
+		return err
+	}
+	r.Config = config
+	return nil
+}
+
+func (r *Remote) resolveConfig(strict bool) (map[string]string, error) {
+	if r.Config == nil {
+		return nil, nil
+	}
+
+	missing := map[string]bool{}
+	mapper := func(name string) string {
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			missing[name] = true
+		}
+		return val
+	}
+
+	config := make(map[string]string, len(r.Config))
+	for k, v := range r.Config {
+		config[k] = os.Expand(v, mapper)
+	}
+
+	if strict && len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for x := range missing {
+			names = append(names, x)
+		}
+		sort.Strings(names)
+		return nil, errors.Errorf("remote %s: config references unset environment variables: %s", r.ID, strings.Join(names, ", "))
+	}
+
+	return config, nil
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"os"
+	"testing"
+)
+
+func setenv(t *testing.T, key, val string) func() {
+	t.Helper()
+	old, had := os.LookupEnv(key)
+	if err := os.Setenv(key, val); err != nil {
+		t.Fatalf("could not set %s: %v", key, err)
+	}
+	return func() {
+		if had {
+			//nolint:errcheck
+			os.Setenv(key, old)
+			return
+		}
+		//nolint:errcheck
+		os.Unsetenv(key)
+	}
+}
+
+func TestRemoteResolveEnv(t *testing.T) {
+	defer setenv(t, "LAFORGE_TEST_BUCKET", "competition-state")()
+	//nolint:errcheck
+	os.Unsetenv("LAFORGE_TEST_UNSET")
+
+	r := &Remote{
+		ID:   "state",
+		Type: "s3",
+		Config: map[string]string{
+			"bucket": "${LAFORGE_TEST_BUCKET}",
+			"key":    "$LAFORGE_TEST_UNSET/terraform.tfstate",
+		},
+	}
+	if err := r.ResolveEnv(false); err != nil {
+		t.Fatalf("ResolveEnv returned an error: %v", err)
+	}
+	if r.Config["bucket"] != "competition-state" {
+		t.Fatalf("bucket resolved to %q, expected competition-state", r.Config["bucket"])
+	}
+	if r.Config["key"] != "/terraform.tfstate" {
+		t.Fatalf("key resolved to %q, expected /terraform.tfstate", r.Config["key"])
+	}
+}
+
+func TestRemoteResolveEnvStrict(t *testing.T) {
+	defer setenv(t, "LAFORGE_TEST_BUCKET", "competition-state")()
+	//nolint:errcheck
+	os.Unsetenv("LAFORGE_TEST_UNSET")
+
+	r := &Remote{
+		ID:   "state",
+		Type: "s3",
+		Config: map[string]string{
+			"bucket": "${LAFORGE_TEST_BUCKET}",
+			"key":    "${LAFORGE_TEST_UNSET}",
+		},
+	}
+	if err := r.ResolveEnv(true); err == nil {
+		t.Fatal("expected an error resolving an unset variable in strict mode")
+	}
+	if r.Config["bucket"] != "${LAFORGE_TEST_BUCKET}" || r.Config["key"] != "${LAFORGE_TEST_UNSET}" {
+		t.Fatalf("Config was modified by a failed strict resolve: %v", r.Config)
+	}
+}
+
+func TestRemoteHashResolvesEnv(t *testing.T) {
+	defer setenv(t, "LAFORGE_TEST_BUCKET", "competition-state")()
+
+	a := &Remote{ID: "state", Type: "s3", Config: map[string]string{"bucket": "${LAFORGE_TEST_BUCKET}"}}
+	b := &Remote{ID: "state", Type: "s3", Config: map[string]string{"bucket": "competition-state"}}
+	if a.Hash() != b.Hash() {
+		t.Fatal("expected remotes which resolve to the same config to hash equal")
+	}
+}
+
+func TestRemoteDeepCopy(t *testing.T) {
+	r := &Remote{ID: "state", Type: "s3", Config: map[string]string{"bucket": "competition-state"}}
+	cp := r.DeepCopy()
+	cp.Config["bucket"] = "changed"
+	cp.Config["key"] = "added"
+
+	if r.Config["bucket"] != "competition-state" {
+		t.Fatalf("original bucket was modified to %q", r.Config["bucket"])
+	}
+	if _, ok := r.Config["key"]; ok {
+		t.Fatal("key added to the copy leaked into the original")
+	}
+}
+
+func TestRemoteValidate(t *testing.T) {
+	defer setenv(t, "LAFORGE_TEST_BUCKET", "competition-state")()
+	//nolint:errcheck
+	os.Unsetenv("LAFORGE_TEST_UNSET")
+
+	cases := []struct {
+		name    string
+		remote  *Remote
+		wantErr bool
+	}{
+		{
+			name:   "resolved",
+			remote: &Remote{ID: "state", Type: "s3", Config: map[string]string{"bucket": "${LAFORGE_TEST_BUCKET}", "key": "state"}},
+		},
+		{
+			name:    "unknown backend",
+			remote:  &Remote{ID: "state", Type: "ftp"},
+			wantErr: true,
+		},
+		{
+			name:    "missing key",
+			remote:  &Remote{ID: "state", Type: "s3", Config: map[string]string{"bucket": "competition-state"}},
+			wantErr: true,
+		},
+		{
+			name:    "unset variable",
+			remote:  &Remote{ID: "state", Type: "s3", Config: map[string]string{"bucket": "${LAFORGE_TEST_UNSET}", "key": "state"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.remote.Validate()
+			if tc.wantErr && err == nil {
+				t.Fatal("expected Validate to return an error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("Validate returned an error: %v", err)
+			}
+		})
+	}
+}
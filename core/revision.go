@@ -4,10 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path"
+	"sort"
 	"time"
 
 	"path/filepath"
+
+	"github.com/cespare/xxhash"
+	"github.com/karrick/godirwalk"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -37,8 +43,17 @@ const (
 
 	// RevModRebuild describes a revision that needs to be rebuilt due to human declaration
 	RevModRebuild RevMod = `REBUILD`
+
+	// RevisionSchemaVersion is the current on disk format version of revision files
+	RevisionSchemaVersion = 1
 )
 
+// OnRevisionStatusChange is an optional hook called whenever a revision transition (Touch, Taint, MarkFailed or
+// Untaint) changes a revision's status. It is nil by default. It is read without synchronization, so it must be set
+// before any revisions are transitioned and not changed afterwards. It may be called concurrently from the planner's
+// walker goroutines and must be safe for concurrent use.
+var OnRevisionStatusChange func(r *Revision, from, to RevStatus)
+
 // RevStatus is a type used to describe the current state of the revision
 type RevStatus string
 
@@ -48,37 +63,240 @@ type RevMod string
 // Revision is used to describe a small .lfrevision file placed in the root of each path
 //easyjson:json
 type Revision struct {
-	ID         string            `json:"id"`
-	Type       LFType            `json:"type"`
-	Status     RevStatus         `json:"status"`
-	Checksum   uint64            `json:"checksum"`
-	Timestamp  time.Time         `json:"timestamp"`
-	ExternalID string            `json:"external_id"`
-	Vars       map[string]string `json:"vars"`
+	ID            string            `json:"id"`
+	Type          LFType            `json:"type"`
+	Status        RevStatus         `json:"status"`
+	Checksum      uint64            `json:"checksum"`
+	Timestamp     time.Time         `json:"timestamp"`
+	ExternalID    string            `json:"external_id"`
+	ExternalIDs   []string          `json:"external_ids"`
+	SchemaVersion int               `json:"schema_version"`
+	Vars          map[string]string `json:"vars"`
+	Path          string            `json:"-"`
+}
+
+// ComputeChecksum returns an xxhash of the stable fields of the revision (ID, type, external IDs and sorted vars)
+func (r *Revision) ComputeChecksum() uint64 {
+	return xxhash.Sum64String(
+		fmt.Sprintf(
+			"id=%v type=%v external_id=%v external_ids=%v vars=%v",
+			r.ID,
+			r.Type,
+			r.ExternalID,
+			r.ExternalIDs,
+			HashStringMap(r.Vars),
+		),
+	)
+}
+
+// DeepCopy returns a copy of the revision which shares no maps or slices with the original
+func (r *Revision) DeepCopy() *Revision {
+	cp := *r
+	if r.ExternalIDs != nil {
+		cp.ExternalIDs = make([]string, len(r.ExternalIDs))
+		copy(cp.ExternalIDs, r.ExternalIDs)
+	}
+	if r.Vars != nil {
+		cp.Vars = make(map[string]string, len(r.Vars))
+		for k, v := range r.Vars {
+			cp.Vars[k] = v
+		}
+	}
+	return &cp
 }
 
-// Touch sets the current timestamp and status to active for use within templating engines
+// Hash implements the Hasher interface
+func (r *Revision) Hash() uint64 {
+	return r.ComputeChecksum()
+}
+
+// Touch sets the current timestamp and status to active for use within templating engines.
+// If the revision does not already carry a checksum (such as one derived from its metadata), one is computed.
 func (r *Revision) Touch() *Revision {
-	r.Status = RevStatusActive
-	r.Timestamp = time.Now()
+	if r.Checksum == 0 {
+		r.Checksum = r.ComputeChecksum()
+	}
+	r.setStatus(RevStatusActive)
 	return r
 }
 
-// TouchWithID touches the revision and updates it's External ID resource
+// TouchWithID touches the revision and records s as one of it's External ID resources
 func (r *Revision) TouchWithID(s string) *Revision {
+	return r.TouchWithIDs(s)
+}
+
+// TouchWithIDs touches the revision and records every provided External ID resource it owns.
+// ExternalID is kept as the first recorded ID for backwards compatibility.
+func (r *Revision) TouchWithIDs(ids ...string) *Revision {
+	for _, id := range ids {
+		found := false
+		for _, x := range r.ExternalIDs {
+			if x == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.ExternalIDs = append(r.ExternalIDs, id)
+		}
+	}
+	if r.ExternalID == "" && len(r.ExternalIDs) > 0 {
+		r.ExternalID = r.ExternalIDs[0]
+	}
 	r.Touch()
-	r.ExternalID = s
 	return r
 }
 
-// Taint changes the revision to one that is a stale state
+// Taint changes the revision to one that is a stale state, preserving its checksum
 func (r *Revision) Taint() *Revision {
-	r.Status = RevStatusStale
-	r.Timestamp = time.Now()
-	r.Checksum = 666
+	r.setStatus(RevStatusStale)
+	return r
+}
+
+// MarkFailed changes the revision to one that has failed to be created
+func (r *Revision) MarkFailed() *Revision {
+	r.setStatus(RevStatusFailed)
 	return r
 }
 
+// Untaint returns a tainted revision to an active state, preserving its checksum
+func (r *Revision) Untaint() *Revision {
+	r.setStatus(RevStatusActive)
+	return r
+}
+
+// IsActive returns true if the revision has been created successfully
+func (r *Revision) IsActive() bool {
+	return r.Status == RevStatusActive
+}
+
+// IsStale returns true if the revision is no longer used by outside systems
+func (r *Revision) IsStale() bool {
+	return r.Status == RevStatusStale
+}
+
+// IsFailed returns true if the revision failed to be created
+func (r *Revision) IsFailed() bool {
+	return r.Status == RevStatusFailed
+}
+
+// IsPlanned returns true if the revision has been planned but not yet created
+func (r *Revision) IsPlanned() bool {
+	return r.Status == RevStatusPlanned
+}
+
+// IsUnknown returns true if the state of the revision's resource is unknown
+func (r *Revision) IsUnknown() bool {
+	return r.Status == RevStatusUnknown
+}
+
+// setStatus updates the status and timestamp of the revision, notifying OnRevisionStatusChange if the status changed
+func (r *Revision) setStatus(to RevStatus) {
+	from := r.Status
+	r.Status = to
+	r.Timestamp = time.Now()
+	if from != to && OnRevisionStatusChange != nil {
+		OnRevisionStatusChange(r, from, to)
+	}
+}
+
+// Age returns how long ago the revision was last updated
+func (r *Revision) Age() time.Duration {
+	return time.Since(r.Timestamp)
+}
+
+// Diff returns a human readable list of the fields that differ between r and other. Timestamps are not compared.
+// A nil revision is treated as absent.
+func (r *Revision) Diff(other *Revision) []string {
+	diffs := []string{}
+	switch {
+	case r == nil && other == nil:
+		return diffs
+	case r == nil:
+		return append(diffs, fmt.Sprintf("revision: (absent) -> %s", other.ID))
+	case other == nil:
+		return append(diffs, fmt.Sprintf("revision: %s -> (absent)", r.ID))
+	}
+	if r.ID != other.ID {
+		diffs = append(diffs, fmt.Sprintf("id: %s -> %s", r.ID, other.ID))
+	}
+	if r.Type != other.Type {
+		diffs = append(diffs, fmt.Sprintf("type: %s -> %s", r.Type, other.Type))
+	}
+	if r.Status != other.Status {
+		diffs = append(diffs, fmt.Sprintf("status: %s -> %s", r.Status, other.Status))
+	}
+	if r.Checksum != other.Checksum {
+		diffs = append(diffs, fmt.Sprintf("checksum: %d -> %d", r.Checksum, other.Checksum))
+	}
+	if r.ExternalID != other.ExternalID {
+		diffs = append(diffs, fmt.Sprintf("external_id: %s -> %s", r.ExternalID, other.ExternalID))
+	}
+	if !stringSlicesEqual(r.ExternalIDs, other.ExternalIDs) {
+		diffs = append(diffs, fmt.Sprintf("external_ids: %v -> %v", r.ExternalIDs, other.ExternalIDs))
+	}
+
+	keys := []string{}
+	for k := range r.Vars {
+		keys = append(keys, k)
+	}
+	for k := range other.Vars {
+		if _, ok := r.Vars[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		oldval, oldok := r.Vars[k]
+		newval, newok := other.Vars[k]
+		switch {
+		case !oldok:
+			diffs = append(diffs, fmt.Sprintf("vars[%s]: (added) -> %s", k, newval))
+		case !newok:
+			diffs = append(diffs, fmt.Sprintf("vars[%s]: %s -> (removed)", k, oldval))
+		case oldval != newval:
+			diffs = append(diffs, fmt.Sprintf("vars[%s]: %s -> %s", k, oldval, newval))
+		}
+	}
+
+	return diffs
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// PlanRevision determines what needs to happen to bring the current (on disk) revision in line with the desired one.
+// A stale or failed revision is rebuilt. This is the single source of the rules used by State.GenerateRevisionDelta.
+// If neither revision exists, an empty RevMod is returned.
+func PlanRevision(desired, current *Revision) RevMod {
+	switch {
+	case desired == nil && current == nil:
+		return RevMod("")
+	case current == nil:
+		return RevModCreate
+	case desired == nil:
+		return RevModDelete
+	case desired.Checksum != current.Checksum:
+		return RevModRebuild
+	case current.IsFailed():
+		return RevModRebuild
+	case desired.IsStale() || current.IsStale():
+		return RevModRebuild
+	default:
+		return RevModTouch
+	}
+}
+
 // AbsPath returns a joined file path for build types and below
 func (r *Revision) AbsPath(basedir string) string {
 	if r.Type == LFTypeEnvironment {
@@ -87,6 +305,9 @@ func (r *Revision) AbsPath(basedir string) string {
 	if r.Type == LFTypeConnection {
 		return filepath.Join(basedir, filepath.Dir(r.ID), r.Filename())
 	}
+	if r.Type == LFTypeProvisioningStep {
+		return filepath.Join(basedir, filepath.Dir(r.ID), r.Filename())
+	}
 	return filepath.Join(basedir, r.ID, r.Filename())
 }
 
@@ -112,9 +333,208 @@ func ParseRevisionFile(fpath string) (*Revision, error) {
 		return nil, err
 	}
 
+	if rev.SchemaVersion > RevisionSchemaVersion {
+		return nil, errors.Errorf("revfile=%s: schema version %d is newer than the supported version %d", fpath, rev.SchemaVersion, RevisionSchemaVersion)
+	}
+
+	rev.migrate()
+	rev.Path, err = filepath.Abs(fpath)
+	if err != nil {
+		return nil, err
+	}
 	return &rev, nil
 }
 
+// migrate upgrades a revision parsed from an older on disk format to the current RevisionSchemaVersion
+func (r *Revision) migrate() {
+	if r.SchemaVersion < 1 {
+		if r.ExternalID != "" && len(r.ExternalIDs) == 0 {
+			r.ExternalIDs = []string{r.ExternalID}
+		}
+	}
+	r.SchemaVersion = RevisionSchemaVersion
+}
+
+// ParseRevisionDir walks basedir and parses every revision file found within it. Files which fail to parse
+// are collected into a MultiError alongside the revisions that were parsed successfully.
+func ParseRevisionDir(basedir string) ([]*Revision, error) {
+	revs := []*Revision{}
+	errs := MultiError{}
+	err := godirwalk.Walk(basedir, &godirwalk.Options{
+		Callback: func(osPathname string, de *godirwalk.Dirent) error {
+			if de.IsDir() || filepath.Ext(de.Name()) != `.lfrevision` {
+				return nil
+			}
+			rev, err := ParseRevisionFile(osPathname)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "revfile=%s", osPathname))
+				return nil
+			}
+			revs = append(revs, rev)
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return revs, errs.ErrOrNil()
+}
+
+// ResolvePath returns the absolute location of the revision file under basedir. Environment revisions live within the
+// build directory, so builddir is required to locate them. The revision's stored Path is not consulted.
+func (r *Revision) ResolvePath(basedir, builddir string) (string, error) {
+	var fpath string
+	if r.Type == LFTypeEnvironment {
+		if builddir == "" {
+			return "", errors.Errorf("rev=%s: a build directory is required to locate an environment revision", r.ID)
+		}
+		fpath = filepath.Join(builddir, r.AbsPath(basedir))
+	} else {
+		fpath = r.AbsPath(basedir)
+	}
+	if !filepath.IsAbs(fpath) {
+		return "", errors.Errorf("rev=%s: revision path %s is not absolute", r.ID, fpath)
+	}
+	return fpath, nil
+}
+
+// WriteToFile atomically writes the revision to its location under basedir (see ResolvePath), returning the path
+// that was written. The revision's Path is updated to the written location.
+func (r *Revision) WriteToFile(basedir, builddir string) (string, error) {
+	fpath, err := r.ResolvePath(basedir, builddir)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.writeFile(fpath)
+	if err != nil {
+		return "", err
+	}
+
+	r.Path = fpath
+	return fpath, nil
+}
+
+// Save atomically writes the revision back to the file it was parsed from or last written to
+func (r *Revision) Save() error {
+	if r.Path == "" {
+		return errors.Errorf("rev=%s: revision has no path to save to", r.ID)
+	}
+	return r.writeFile(r.Path)
+}
+
+// writeFile atomically writes the revision to fpath by renaming a temporary file into place
+func (r *Revision) writeFile(fpath string) error {
+	tmpname, err := r.writeTempFile(fpath)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpname, fpath)
+	if err != nil {
+		//nolint:gosec,errcheck
+		os.Remove(tmpname)
+		return err
+	}
+
+	return nil
+}
+
+// WriteRevisions writes a batch of revisions to their locations (see ResolvePath). Every revision is first written to
+// a temporary file beside its destination and only once all of them have been staged are they renamed into place, so a
+// failure while staging leaves none of the batch on disk. Batches in which two revisions resolve to the same path are
+// rejected before anything is written. Revisions which could not be written are reported in a MultiError.
+func WriteRevisions(basedir, builddir string, revs []*Revision) error {
+	type staged struct {
+		rev     *Revision
+		fpath   string
+		tmpname string
+	}
+
+	fpaths := make([]string, len(revs))
+	owners := map[string]string{}
+	errs := MultiError{}
+	for i, r := range revs {
+		fpath, err := r.ResolvePath(basedir, builddir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if owner, ok := owners[fpath]; ok {
+			errs = append(errs, errors.Errorf("rev=%s: path %s is already claimed by rev=%s", r.ID, fpath, owner))
+			continue
+		}
+		owners[fpath] = r.ID
+		fpaths[i] = fpath
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	stagedRevs := make([]staged, 0, len(revs))
+	for i, r := range revs {
+		tmpname, err := r.writeTempFile(fpaths[i])
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "rev=%s", r.ID))
+			continue
+		}
+		stagedRevs = append(stagedRevs, staged{rev: r, fpath: fpaths[i], tmpname: tmpname})
+	}
+
+	if len(errs) > 0 {
+		for _, x := range stagedRevs {
+			//nolint:gosec,errcheck
+			os.Remove(x.tmpname)
+		}
+		return errs
+	}
+
+	for _, x := range stagedRevs {
+		err := os.Rename(x.tmpname, x.fpath)
+		if err != nil {
+			//nolint:gosec,errcheck
+			os.Remove(x.tmpname)
+			errs = append(errs, errors.Wrapf(err, "rev=%s", x.rev.ID))
+			continue
+		}
+		x.rev.Path = x.fpath
+	}
+
+	return errs.ErrOrNil()
+}
+
+// writeTempFile writes the revision to a temporary file in the directory of fpath, returning the temporary file's name
+func (r *Revision) writeTempFile(fpath string) (string, error) {
+	dir := filepath.Dir(fpath)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	tmpfile, err := ioutil.TempFile(dir, ".lfrevision-")
+	if err != nil {
+		return "", err
+	}
+	tmpname := tmpfile.Name()
+
+	r.SchemaVersion = RevisionSchemaVersion
+	_, err = tmpfile.WriteString(r.ToJSONString())
+	if err == nil {
+		err = tmpfile.Chmod(0644)
+	}
+	if cerr := tmpfile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		//nolint:gosec,errcheck
+		os.Remove(tmpname)
+		return "", err
+	}
+
+	return tmpname, nil
+}
+
 // ToJSONString converts the revision to a JSON string
 func (r *Revision) ToJSONString() string {
 	data, _ := json.Marshal(r)
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RevisionCache is a concurrency safe, in-memory store of revisions keyed by their absolute location on disk
+type RevisionCache struct {
+	BaseDir  string
+	BuildDir string
+
+	lock    sync.RWMutex
+	entries map[string]revisionCacheEntry
+}
+
+type revisionCacheEntry struct {
+	rev     *Revision
+	modTime time.Time
+}
+
+// NewRevisionCache creates an empty revision cache for revisions rooted at basedir whose environment revision lives in builddir
+func NewRevisionCache(basedir, builddir string) *RevisionCache {
+	return &RevisionCache{
+		BaseDir:  basedir,
+		BuildDir: builddir,
+		entries:  map[string]revisionCacheEntry{},
+	}
+}
+
+// Get returns a copy of the cached revision at fpath, if one exists
+func (c *RevisionCache) Get(fpath string) (*Revision, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entry, ok := c.entries[cacheKey(fpath)]
+	if !ok {
+		return nil, false
+	}
+	return entry.rev.DeepCopy(), true
+}
+
+// Put stores a copy of r in the cache, keyed by its Path or, if it has none, its resolved location within the cache's
+// directories (see Revision.ResolvePath). An error is returned if the location cannot be resolved or is already held
+// by a different revision.
+func (c *RevisionCache) Put(r *Revision) error {
+	key := cacheKey(r.Path)
+	if r.Path == "" {
+		var err error
+		key, err = r.ResolvePath(c.BaseDir, c.BuildDir)
+		if err != nil {
+			return err
+		}
+	}
+	return c.put(key, r, time.Time{})
+}
+
+// Invalidate removes the revision at fpath from the cache
+func (c *RevisionCache) Invalidate(fpath string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, cacheKey(fpath))
+}
+
+// ParseRevisionFile returns the cached revision at fpath when the file has not been modified since it was cached,
+// otherwise parsing it from disk and updating the cache.
+func (c *RevisionCache) ParseRevisionFile(fpath string) (*Revision, error) {
+	fpath = cacheKey(fpath)
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		c.Invalidate(fpath)
+		return nil, err
+	}
+
+	c.lock.RLock()
+	entry, ok := c.entries[fpath]
+	c.lock.RUnlock()
+	if ok && entry.modTime.Equal(fi.ModTime()) {
+		return entry.rev.DeepCopy(), nil
+	}
+
+	rev, err := ParseRevisionFile(fpath)
+	if err != nil {
+		c.Invalidate(fpath)
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.store(fpath, rev, fi.ModTime())
+	c.lock.Unlock()
+	return rev, nil
+}
+
+func (c *RevisionCache) put(key string, r *Revision, modTime time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry, ok := c.entries[key]; ok && entry.rev.ID != r.ID {
+		return errors.Errorf("rev=%s: cache key %s is already held by rev=%s", r.ID, key, entry.rev.ID)
+	}
+	c.store(key, r, modTime)
+	return nil
+}
+
+// cacheKey normalizes fpath into the absolute, cleaned form entries are keyed by
+func cacheKey(fpath string) string {
+	if fpath == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(fpath); err == nil {
+		return abs
+	}
+	return filepath.Clean(fpath)
+}
+
+// store must be called with the write lock held
+func (c *RevisionCache) store(key string, r *Revision, modTime time.Time) {
+	if c.entries == nil {
+		c.entries = map[string]revisionCacheEntry{}
+	}
+	c.entries[key] = revisionCacheEntry{
+		rev:     r.DeepCopy(),
+		modTime: modTime,
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRevisionCachePut(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+	builddir := filepath.Join(basedir, "envs", "test", "gcp")
+	cache := NewRevisionCache(basedir, builddir)
+
+	env := &Revision{ID: "/envs/test", Type: LFTypeEnvironment}
+	if err := cache.Put(env); err != nil {
+		t.Fatalf("Put returned an error: %v", err)
+	}
+	if _, ok := cache.Get(filepath.Join(builddir, ".env.lfrevision")); !ok {
+		t.Fatal("expected the environment revision to be keyed by its absolute path within the build directory")
+	}
+
+	other := &Revision{ID: "/envs/other", Type: LFTypeEnvironment}
+	if err := cache.Put(other); err == nil {
+		t.Fatal("expected an error putting a second environment revision at the same location")
+	}
+	rev, _ := cache.Get(filepath.Join(builddir, ".env.lfrevision"))
+	if rev.ID != env.ID {
+		t.Fatalf("cached revision was overwritten by %s", rev.ID)
+	}
+
+	if err := NewRevisionCache(basedir, "").Put(env); err == nil {
+		t.Fatal("expected an error putting an environment revision without a build directory")
+	}
+}
+
+func TestRevisionCacheParseRevisionFile(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+	cache := NewRevisionCache(basedir, "")
+
+	host := &Revision{ID: "/hosts/web", Type: LFTypeHost}
+	fpath, err := host.WriteToFile(basedir, "")
+	if err != nil {
+		t.Fatalf("WriteToFile returned an error: %v", err)
+	}
+
+	if _, err := cache.ParseRevisionFile(fpath); err != nil {
+		t.Fatalf("ParseRevisionFile returned an error: %v", err)
+	}
+	if _, ok := cache.Get(fpath); !ok {
+		t.Fatalf("expected the parsed revision to be cached at %s", fpath)
+	}
+	if err := cache.Put(host); err != nil {
+		t.Fatalf("expected re-putting the same revision to update its entry: %v", err)
+	}
+}
+
+func TestRevisionCacheRelativeKeys(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+	cache := NewRevisionCache(basedir, "")
+
+	fpath, err := (&Revision{ID: "/hosts/web", Type: LFTypeHost}).WriteToFile(basedir, "")
+	if err != nil {
+		t.Fatalf("WriteToFile returned an error: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+	relpath, err := filepath.Rel(wd, fpath)
+	if err != nil {
+		t.Fatalf("could not make %s relative: %v", fpath, err)
+	}
+
+	if _, err := cache.ParseRevisionFile(relpath); err != nil {
+		t.Fatalf("ParseRevisionFile returned an error: %v", err)
+	}
+	if _, ok := cache.Get(relpath); !ok {
+		t.Fatalf("expected Get(%s) to hit the entry cached by ParseRevisionFile", relpath)
+	}
+	if _, ok := cache.Get(fpath); !ok {
+		t.Fatalf("expected Get(%s) to hit the entry cached by ParseRevisionFile", fpath)
+	}
+
+	cache.Invalidate(relpath)
+	if _, ok := cache.Get(fpath); ok {
+		t.Fatalf("expected Invalidate(%s) to remove the cached entry", relpath)
+	}
+}
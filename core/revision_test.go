@@ -0,0 +1,395 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "laforge-revision-")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+	return dir, func() {
+		//nolint:errcheck
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRevisionWriteToFile(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+	builddir := filepath.Join(basedir, "envs", "test", "gcp")
+
+	cases := []struct {
+		name string
+		rev  *Revision
+		want string
+	}{
+		{
+			name: "environment",
+			rev:  &Revision{ID: "/envs/test", Type: LFTypeEnvironment},
+			want: filepath.Join(builddir, ".env.lfrevision"),
+		},
+		{
+			name: "host",
+			rev:  &Revision{ID: "/hosts/web", Type: LFTypeHost},
+			want: filepath.Join(basedir, "hosts", "web", ".host.lfrevision"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fpath, err := tc.rev.WriteToFile(basedir, builddir)
+			if err != nil {
+				t.Fatalf("WriteToFile returned an error: %v", err)
+			}
+			if fpath != tc.want {
+				t.Fatalf("WriteToFile wrote %s, expected %s", fpath, tc.want)
+			}
+			rev, err := ParseRevisionFile(fpath)
+			if err != nil {
+				t.Fatalf("could not parse written revision: %v", err)
+			}
+			if rev.ID != tc.rev.ID {
+				t.Fatalf("parsed revision has ID %s, expected %s", rev.ID, tc.rev.ID)
+			}
+		})
+	}
+}
+
+func TestRevisionWriteToFileRejectsUnresolvablePaths(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+
+	env := &Revision{ID: "/envs/test", Type: LFTypeEnvironment}
+	if _, err := env.WriteToFile(basedir, ""); err == nil {
+		t.Fatal("expected an error writing an environment revision without a build directory")
+	}
+
+	host := &Revision{ID: "/hosts/web", Type: LFTypeHost}
+	if _, err := host.WriteToFile("relative", ""); err == nil {
+		t.Fatal("expected an error writing a revision to a relative path")
+	}
+}
+
+func TestWriteRevisions(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+	builddir := filepath.Join(basedir, "envs", "test", "gcp")
+
+	revs := []*Revision{
+		{ID: "/envs/test", Type: LFTypeEnvironment},
+		{ID: "/hosts/web", Type: LFTypeHost},
+	}
+	if err := WriteRevisions(basedir, builddir, revs); err != nil {
+		t.Fatalf("WriteRevisions returned an error: %v", err)
+	}
+
+	for _, fpath := range []string{
+		filepath.Join(builddir, ".env.lfrevision"),
+		filepath.Join(basedir, "hosts", "web", ".host.lfrevision"),
+	} {
+		if _, err := os.Stat(fpath); err != nil {
+			t.Fatalf("expected revision at %s: %v", fpath, err)
+		}
+	}
+}
+
+func TestWriteRevisionsRejectsDuplicatePaths(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+	builddir := filepath.Join(basedir, "envs", "test", "gcp")
+
+	revs := []*Revision{
+		{ID: "/hosts/web", Type: LFTypeHost},
+		{ID: "/envs/test", Type: LFTypeEnvironment},
+		{ID: "/envs/other", Type: LFTypeEnvironment},
+	}
+	if err := WriteRevisions(basedir, builddir, revs); err == nil {
+		t.Fatal("expected an error writing two environment revisions to the same build directory")
+	}
+
+	if _, err := os.Stat(filepath.Join(basedir, "hosts", "web", ".host.lfrevision")); !os.IsNotExist(err) {
+		t.Fatalf("expected no revisions to be written from a rejected batch, got err=%v", err)
+	}
+}
+
+func TestPlanRevision(t *testing.T) {
+	rev := func(checksum uint64, status RevStatus) *Revision {
+		return &Revision{ID: "/hosts/web", Type: LFTypeHost, Checksum: checksum, Status: status}
+	}
+
+	cases := []struct {
+		name    string
+		desired *Revision
+		current *Revision
+		want    RevMod
+	}{
+		{"nil/nil", nil, nil, RevMod("")},
+		{"nil/current", nil, rev(1, RevStatusActive), RevModDelete},
+		{"desired/nil", rev(1, RevStatusPlanned), nil, RevModCreate},
+		{"checksum mismatch", rev(2, RevStatusPlanned), rev(1, RevStatusActive), RevModRebuild},
+		{"current failed", rev(1, RevStatusPlanned), rev(1, RevStatusFailed), RevModRebuild},
+		{"desired stale", rev(1, RevStatusStale), rev(1, RevStatusActive), RevModRebuild},
+		{"current stale", rev(1, RevStatusPlanned), rev(1, RevStatusStale), RevModRebuild},
+		{"equal", rev(1, RevStatusPlanned), rev(1, RevStatusActive), RevModTouch},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := PlanRevision(tc.desired, tc.current); got != tc.want {
+				t.Fatalf("PlanRevision returned %q, expected %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRevisionAbsPath(t *testing.T) {
+	basedir := filepath.Join(string(filepath.Separator), "basedir")
+
+	cases := []struct {
+		name string
+		rev  *Revision
+		want string
+	}{
+		{
+			name: "environment",
+			rev:  &Revision{ID: "/envs/test", Type: LFTypeEnvironment},
+			want: ".env.lfrevision",
+		},
+		{
+			name: "connection",
+			rev:  &Revision{ID: "host/conn1", Type: LFTypeConnection},
+			want: filepath.Join(basedir, "host", ".connection.lfrevision"),
+		},
+		{
+			name: "provisioning step",
+			rev:  &Revision{ID: "host/step1", Type: LFTypeProvisioningStep},
+			want: filepath.Join(basedir, "host", ".step1.pstep.lfrevision"),
+		},
+		{
+			name: "default",
+			rev:  &Revision{ID: "/hosts/web", Type: LFTypeHost},
+			want: filepath.Join(basedir, "hosts", "web", ".host.lfrevision"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rev.AbsPath(basedir); got != tc.want {
+				t.Fatalf("AbsPath returned %s, expected %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseRevisionFileMigratesV0(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+
+	fpath := filepath.Join(basedir, ".host.lfrevision")
+	v0 := `{"id":"/hosts/web","type":"host","status":"ACTIVE","checksum":1,"external_id":"i-1234","vars":{}}`
+	if err := ioutil.WriteFile(fpath, []byte(v0), 0644); err != nil {
+		t.Fatalf("could not write v0 revision: %v", err)
+	}
+
+	rev, err := ParseRevisionFile(fpath)
+	if err != nil {
+		t.Fatalf("ParseRevisionFile returned an error: %v", err)
+	}
+	if rev.SchemaVersion != RevisionSchemaVersion {
+		t.Fatalf("SchemaVersion is %d, expected %d", rev.SchemaVersion, RevisionSchemaVersion)
+	}
+	if len(rev.ExternalIDs) != 1 || rev.ExternalIDs[0] != "i-1234" {
+		t.Fatalf("ExternalIDs is %v, expected [i-1234]", rev.ExternalIDs)
+	}
+	if rev.ExternalID != "i-1234" {
+		t.Fatalf("ExternalID is %s, expected i-1234", rev.ExternalID)
+	}
+}
+
+func TestRevisionDeepCopy(t *testing.T) {
+	r := &Revision{
+		ID:          "/hosts/web",
+		Type:        LFTypeHost,
+		ExternalIDs: []string{"i-1234"},
+		Vars:        map[string]string{"role": "web"},
+	}
+	cp := r.DeepCopy()
+	cp.ExternalIDs[0] = "i-5678"
+	cp.ExternalIDs = append(cp.ExternalIDs, "i-9999")
+	cp.Vars["role"] = "db"
+	cp.Vars["added"] = "true"
+
+	if len(r.ExternalIDs) != 1 || r.ExternalIDs[0] != "i-1234" {
+		t.Fatalf("original ExternalIDs was modified to %v", r.ExternalIDs)
+	}
+	if len(r.Vars) != 1 || r.Vars["role"] != "web" {
+		t.Fatalf("original Vars was modified to %v", r.Vars)
+	}
+}
+
+func TestRevisionHash(t *testing.T) {
+	rev := func() *Revision {
+		return &Revision{
+			ID:          "/hosts/web",
+			Type:        LFTypeHost,
+			ExternalID:  "i-1234",
+			ExternalIDs: []string{"i-1234"},
+			Vars:        map[string]string{"role": "web"},
+		}
+	}
+
+	if rev().Hash() != rev().Hash() {
+		t.Fatal("expected identical revisions to hash equal")
+	}
+
+	mutations := map[string]func(r *Revision){
+		"id":           func(r *Revision) { r.ID = "/hosts/db" },
+		"type":         func(r *Revision) { r.Type = LFTypeNetwork },
+		"external_id":  func(r *Revision) { r.ExternalID = "i-5678" },
+		"external_ids": func(r *Revision) { r.ExternalIDs = append(r.ExternalIDs, "i-5678") },
+		"vars":         func(r *Revision) { r.Vars["role"] = "db" },
+	}
+	for field, mutate := range mutations {
+		t.Run(field, func(t *testing.T) {
+			r := rev()
+			mutate(r)
+			if r.Hash() == rev().Hash() {
+				t.Fatalf("expected changing %s to change the hash", field)
+			}
+		})
+	}
+}
+
+func TestRevisionDiff(t *testing.T) {
+	r := &Revision{ID: "/hosts/web", Type: LFTypeHost, ExternalIDs: []string{"a,b"}}
+
+	if diffs := r.Diff(nil); len(diffs) != 1 {
+		t.Fatalf("expected a single diff against an absent revision, got %v", diffs)
+	}
+	if diffs := (*Revision)(nil).Diff(r); len(diffs) != 1 {
+		t.Fatalf("expected a single diff from an absent revision, got %v", diffs)
+	}
+	if diffs := r.Diff(r.DeepCopy()); len(diffs) != 0 {
+		t.Fatalf("expected no diffs between identical revisions, got %v", diffs)
+	}
+
+	other := r.DeepCopy()
+	other.ExternalIDs = []string{"a", "b"}
+	if diffs := r.Diff(other); len(diffs) != 1 {
+		t.Fatalf("expected an external_ids diff, got %v", diffs)
+	}
+}
+
+func TestRevisionWriteToFileIgnoresStoredPath(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+	otherdir, othercleanup := tempDir(t)
+	defer othercleanup()
+
+	orig := &Revision{ID: "/hosts/web", Type: LFTypeHost, Vars: map[string]string{"role": "web"}}
+	origpath, err := orig.WriteToFile(basedir, "")
+	if err != nil {
+		t.Fatalf("WriteToFile returned an error: %v", err)
+	}
+
+	cp := orig.DeepCopy()
+	cp.Vars["role"] = "db"
+	cppath, err := cp.WriteToFile(otherdir, "")
+	if err != nil {
+		t.Fatalf("WriteToFile returned an error: %v", err)
+	}
+	if cppath != filepath.Join(otherdir, "hosts", "web", ".host.lfrevision") {
+		t.Fatalf("copy was written to %s instead of the requested base dir", cppath)
+	}
+
+	rev, err := ParseRevisionFile(origpath)
+	if err != nil {
+		t.Fatalf("could not parse original revision: %v", err)
+	}
+	if rev.Vars["role"] != "web" {
+		t.Fatalf("original revision was overwritten with role=%s", rev.Vars["role"])
+	}
+}
+
+func TestRevisionSave(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+
+	if err := (&Revision{ID: "/hosts/web", Type: LFTypeHost}).Save(); err == nil {
+		t.Fatal("expected an error saving a revision without a path")
+	}
+
+	fpath, err := (&Revision{ID: "/hosts/web", Type: LFTypeHost}).WriteToFile(basedir, "")
+	if err != nil {
+		t.Fatalf("WriteToFile returned an error: %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+	relpath, err := filepath.Rel(wd, fpath)
+	if err != nil {
+		t.Fatalf("could not make %s relative: %v", fpath, err)
+	}
+
+	rev, err := ParseRevisionFile(relpath)
+	if err != nil {
+		t.Fatalf("ParseRevisionFile returned an error: %v", err)
+	}
+	if rev.Path != fpath {
+		t.Fatalf("parsed revision has Path %s, expected %s", rev.Path, fpath)
+	}
+
+	rev.Taint()
+	if err := rev.Save(); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	rev, err = ParseRevisionFile(fpath)
+	if err != nil {
+		t.Fatalf("could not parse saved revision: %v", err)
+	}
+	if !rev.IsStale() {
+		t.Fatalf("saved revision has status %s, expected %s", rev.Status, RevStatusStale)
+	}
+}
+
+func TestOnRevisionStatusChange(t *testing.T) {
+	transitions := []RevStatus{}
+	OnRevisionStatusChange = func(r *Revision, from, to RevStatus) {
+		transitions = append(transitions, to)
+	}
+	defer func() {
+		OnRevisionStatusChange = nil
+	}()
+
+	rev := &Revision{ID: "/hosts/web", Type: LFTypeHost, Status: RevStatusPlanned, Checksum: 1}
+	rev.MarkFailed()
+	rev.MarkFailed()
+	rev.Touch()
+
+	if len(transitions) != 2 || transitions[0] != RevStatusFailed || transitions[1] != RevStatusActive {
+		t.Fatalf("hook observed %v, expected [%s %s]", transitions, RevStatusFailed, RevStatusActive)
+	}
+}
+
+func TestParseRevisionFileRejectsNewerSchema(t *testing.T) {
+	basedir, cleanup := tempDir(t)
+	defer cleanup()
+
+	fpath := filepath.Join(basedir, ".host.lfrevision")
+	future := `{"id":"/hosts/web","type":"host","status":"ACTIVE","checksum":1,"schema_version":99}`
+	if err := ioutil.WriteFile(fpath, []byte(future), 0644); err != nil {
+		t.Fatalf("could not write revision: %v", err)
+	}
+
+	if _, err := ParseRevisionFile(fpath); err == nil {
+		t.Fatal("expected an error parsing a revision from a newer schema version")
+	}
+}
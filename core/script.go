@@ -141,6 +141,23 @@ func (s *Script) Kind() string {
 	return ObjectTypeScript.String()
 }
 
+// Validate implements the Provisioner interface
+func (s *Script) Validate() error {
+	errs := MultiError{}
+	if s.Source == "" {
+		errs = append(errs, errors.Errorf("script %s: source must be specified", s.ID))
+	}
+	if s.AbsPath != "" {
+		if _, err := os.Stat(s.AbsPath); err != nil {
+			errs = append(errs, errors.Wrapf(err, "script %s: source could not be located", s.ID))
+		}
+	}
+	if err := validateVarKeys(s.ID, s.Vars); err != nil {
+		errs = append(errs, err)
+	}
+	return errs.ErrOrNil()
+}
+
 // Swap implements the Mergeable interface
 func (s *Script) Swap(m Mergeable) error {
 	rawVal, ok := m.(*Script)
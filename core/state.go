@@ -174,6 +174,9 @@ func (s *State) GenerateCurrentRevs() error {
 	}
 	for _, x := range s.Current.Metastore {
 		rev := x.ToRevision()
+		if x.Tainted {
+			rev.Taint()
+		}
 		s.NewRevs[rev.ID] = rev
 	}
 	return nil
@@ -188,35 +191,16 @@ func (s *State) GenerateRevisionDelta() error {
 	nrkeys := make([]string, len(s.NewRevs))
 	for nrid, nrev := range s.NewRevs {
 		nrkeys = append(nrkeys, nrid)
-		krev, ok := s.KnownRevs[nrid]
-		if !ok {
-			s.RevDelta[nrid] = RevModCreate
-			continue
-		}
-		if nrev.Checksum != krev.Checksum {
-			cli.Logger.Debugf("Marking %s for a failed revision checksum comparison", nrid)
-			s.RevDelta[nrid] = RevModRebuild
-			continue
-		}
-		if krev.Status == RevStatusFailed {
-			cli.Logger.Debugf("Marking %s for failed state", nrid)
-			s.RevDelta[nrid] = RevModRebuild
-			continue
-		}
-		if nrev.Status == RevStatusStale || krev.Status == RevStatusStale {
-			cli.Logger.Debugf("Marking %s for stale state", nrid)
-			s.RevDelta[nrid] = RevModRebuild
+		mod := PlanRevision(nrev, s.KnownRevs[nrid])
+		if mod == RevModTouch {
 			continue
 		}
-		// if s.Persisted.Metastore[nrid].Checksum != s.Current.Metastore[nrid].Checksum {
-		// 	cli.Logger.Debugf("Marking %s for an induced checksum change", nrid)
-		// 	s.RevDelta[nrid] = RevModTouch
-		// 	continue
-		// }
+		cli.Logger.Debugf("Marking %s for %s", nrid, mod)
+		s.RevDelta[nrid] = mod
 	}
-	for knid := range s.KnownRevs {
+	for knid, krev := range s.KnownRevs {
 		if _, ok := s.NewRevs[knid]; !ok {
-			s.RevDelta[knid] = RevModDelete
+			s.RevDelta[knid] = PlanRevision(nil, krev)
 		}
 	}
 	return nil
@@ -240,6 +224,21 @@ func (s *State) KnownRevHashes() uint64 {
 	return hashes.Hash()
 }
 
+// PendingRevs returns true if any known or new revision is stale or failed and so must be rebuilt regardless of checksums
+func (s *State) PendingRevs() bool {
+	for _, x := range s.KnownRevs {
+		if x.IsStale() || x.IsFailed() {
+			return true
+		}
+	}
+	for _, x := range s.NewRevs {
+		if x.IsStale() || x.IsFailed() {
+			return true
+		}
+	}
+	return false
+}
+
 // SnapshotsEqual are used to test the equality of the two environments and their dependencies
 func (s *State) SnapshotsEqual() bool {
 	if s.Persisted.Hash() != s.Current.Hash() {
@@ -248,6 +247,9 @@ func (s *State) SnapshotsEqual() bool {
 	if s.KnownRevHashes() != s.NewRevHashes() {
 		return false
 	}
+	if s.PendingRevs() {
+		return false
+	}
 	return true
 }
 
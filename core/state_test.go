@@ -0,0 +1,79 @@
+package core
+
+import "testing"
+
+func newTaintTestState(id string, known *Revision, tainted bool) *State {
+	s := NewState()
+	s.Persisted = NewEmptySnapshot()
+	s.Current = NewEmptySnapshot()
+	s.Persisted.Metastore[id] = &Metadata{ID: id, ObjectType: TypeByPath(id), Checksum: known.Checksum}
+	s.Current.Metastore[id] = &Metadata{ID: id, ObjectType: TypeByPath(id), Checksum: known.Checksum, Tainted: tainted}
+	s.KnownRevs[id] = known
+	return s
+}
+
+func TestStateTaintPlansRebuild(t *testing.T) {
+	id := "/hosts/web"
+	active := func() *Revision {
+		return (&Metadata{ID: id, Checksum: 1234}).ToRevision().Touch()
+	}
+
+	cases := []struct {
+		name      string
+		known     *Revision
+		tainted   bool
+		wantEqual bool
+		wantMod   RevMod
+	}{
+		{name: "untouched", known: active(), wantEqual: true},
+		{name: "tainted on disk", known: active().Taint(), wantMod: RevModRebuild},
+		{name: "failed on disk", known: active().MarkFailed(), wantMod: RevModRebuild},
+		{name: "tainted in current snapshot", known: active(), tainted: true, wantMod: RevModRebuild},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTaintTestState(id, tc.known, tc.tainted)
+			if err := s.GenerateCurrentRevs(); err != nil {
+				t.Fatalf("GenerateCurrentRevs returned an error: %v", err)
+			}
+			if err := s.GenerateRevisionDelta(); err != nil {
+				t.Fatalf("GenerateRevisionDelta returned an error: %v", err)
+			}
+			if got := s.SnapshotsEqual(); got != tc.wantEqual {
+				t.Fatalf("SnapshotsEqual returned %v, expected %v", got, tc.wantEqual)
+			}
+			if got := s.RevDelta[id]; got != tc.wantMod {
+				t.Fatalf("RevDelta[%s] is %q, expected %q", id, got, tc.wantMod)
+			}
+		})
+	}
+}
+
+func TestStateGenerateRevisionDelta(t *testing.T) {
+	s := NewState()
+	s.NewRevs["/hosts/added"] = &Revision{ID: "/hosts/added", Type: LFTypeHost, Checksum: 1}
+	s.NewRevs["/hosts/changed"] = &Revision{ID: "/hosts/changed", Type: LFTypeHost, Checksum: 2}
+	s.NewRevs["/hosts/same"] = &Revision{ID: "/hosts/same", Type: LFTypeHost, Checksum: 3}
+	s.KnownRevs["/hosts/changed"] = &Revision{ID: "/hosts/changed", Type: LFTypeHost, Checksum: 1, Status: RevStatusActive}
+	s.KnownRevs["/hosts/same"] = &Revision{ID: "/hosts/same", Type: LFTypeHost, Checksum: 3, Status: RevStatusActive}
+	s.KnownRevs["/hosts/removed"] = &Revision{ID: "/hosts/removed", Type: LFTypeHost, Checksum: 4, Status: RevStatusActive}
+
+	if err := s.GenerateRevisionDelta(); err != nil {
+		t.Fatalf("GenerateRevisionDelta returned an error: %v", err)
+	}
+
+	expected := map[string]RevMod{
+		"/hosts/added":   RevModCreate,
+		"/hosts/changed": RevModRebuild,
+		"/hosts/removed": RevModDelete,
+	}
+	if len(s.RevDelta) != len(expected) {
+		t.Fatalf("RevDelta is %v, expected %v", s.RevDelta, expected)
+	}
+	for id, mod := range expected {
+		if s.RevDelta[id] != mod {
+			t.Fatalf("RevDelta[%s] is %q, expected %q", id, s.RevDelta[id], mod)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ReservedTagPrefixes are tag key prefixes reserved by infrastructure providers which cannot be set by users
+	ReservedTagPrefixes = []string{
+		"aws:",
+	}
+
+	// MaxTagKeyLength is the longest tag key accepted by infrastructure providers
+	MaxTagKeyLength = 128
+)
+
+// NormalizeTagKeys returns a copy of tags with leading and trailing whitespace trimmed from each key,
+// optionally lowercasing them as well. An error is returned if two keys normalize to the same key.
+func NormalizeTagKeys(tags map[string]string, lowercase bool) (map[string]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	normalized := make(map[string]string, len(tags))
+	origins := make(map[string]string, len(tags))
+	errs := MultiError{}
+	for _, orig := range keys {
+		k := strings.TrimSpace(orig)
+		if lowercase {
+			k = strings.ToLower(k)
+		}
+		if prev, ok := origins[k]; ok {
+			errs = append(errs, errors.Errorf("tag keys %q and %q both normalize to %q", prev, orig, k))
+			continue
+		}
+		origins[k] = orig
+		normalized[k] = tags[orig]
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return normalized, nil
+}
+
+// ValidateTagKeys checks every tag key for emptiness, surrounding whitespace, reserved prefixes and excessive length
+func ValidateTagKeys(tags map[string]string) []error {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	errs := []error{}
+	for _, k := range keys {
+		if k == "" {
+			errs = append(errs, errors.New("tags contains an empty key"))
+			continue
+		}
+		if strings.TrimSpace(k) != k {
+			errs = append(errs, errors.Errorf("tag key %q has leading or trailing whitespace", k))
+		}
+		if len(k) > MaxTagKeyLength {
+			errs = append(errs, errors.Errorf("tag key %q is longer than %d characters", k, MaxTagKeyLength))
+		}
+		for _, prefix := range ReservedTagPrefixes {
+			if strings.HasPrefix(strings.ToLower(k), prefix) {
+				errs = append(errs, errors.Errorf("tag key %q uses the reserved prefix %q", k, prefix))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,33 @@
+package core
+
+import "testing"
+
+func TestNormalizeTagKeys(t *testing.T) {
+	tags, err := NormalizeTagKeys(map[string]string{" Team ": "blue", "env": "prod"}, true)
+	if err != nil {
+		t.Fatalf("NormalizeTagKeys returned an error: %v", err)
+	}
+	if len(tags) != 2 || tags["team"] != "blue" || tags["env"] != "prod" {
+		t.Fatalf("NormalizeTagKeys returned %v", tags)
+	}
+
+	if _, err := NormalizeTagKeys(map[string]string{"Team": "blue", " team": "red"}, true); err == nil {
+		t.Fatal("expected an error when keys collide after lowercasing")
+	}
+	if _, err := NormalizeTagKeys(map[string]string{"team": "blue", "team ": "red"}, false); err == nil {
+		t.Fatal("expected an error when keys collide after trimming")
+	}
+	if _, err := NormalizeTagKeys(map[string]string{"Team": "blue", "team": "red"}, false); err != nil {
+		t.Fatalf("expected keys differing only by case to be kept when not lowercasing: %v", err)
+	}
+}
+
+func TestAMINormalizeTagsCollision(t *testing.T) {
+	a := &AMI{ID: "ubuntu", Tags: map[string]string{"Team": "blue", " team": "red"}}
+	if err := a.NormalizeTags(true); err == nil {
+		t.Fatal("expected an error normalizing colliding tag keys")
+	}
+	if len(a.Tags) != 2 || a.Tags["Team"] != "blue" || a.Tags[" team"] != "red" {
+		t.Fatalf("tags were modified by a failed normalization: %v", a.Tags)
+	}
+}